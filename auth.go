@@ -0,0 +1,155 @@
+package socky
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// otpRetention is how long an issued OTP remains valid before it is swept
+// from the RetentionMap.
+const otpRetention = 5 * time.Second
+
+// Authenticator verifies an incoming HTTP request before it is allowed to
+// upgrade to a WebSocket connection. It returns the identity to
+// attach to the resulting Client (see Client.Identity) plus any metadata
+// the caller wants to keep around, e.g. roles or a session ID.
+type Authenticator func(r *http.Request) (identity string, meta map[string]any, err error)
+
+// SetAuthenticator configures a to be run for every login request handled
+// by LoginHandler. Once set, Serve rejects WebSocket upgrades that do not
+// carry a valid, previously issued OTP. The OTP store backing this is
+// created here, lazily, rather than in every Manager's constructor, so a
+// Manager that never calls SetAuthenticator never starts its sweep
+// goroutine.
+func (m *Manager) SetAuthenticator(a Authenticator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authenticator = a
+	if m.otps == nil {
+		m.otps = NewRetentionMap(otpRetention)
+	}
+}
+
+// getAuthenticator returns the Manager's current Authenticator, safe for
+// concurrent use alongside SetAuthenticator.
+func (m *Manager) getAuthenticator() Authenticator {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.authenticator
+}
+
+// LoginHandler runs the configured Authenticator against the request and,
+// on success, issues a short-lived OTP the client can use to complete the
+// WebSocket handshake, e.g. GET /ws?otp=<otp>.
+// Usage: http.HandleFunc("/login", m.LoginHandler)
+func (m *Manager) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	authenticator := m.getAuthenticator()
+	if authenticator == nil {
+		http.Error(w, "no authenticator configured", http.StatusInternalServerError)
+		return
+	}
+
+	identity, meta, err := authenticator(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	otp := m.otps.Issue(identity, meta)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		OTP string `json:"otp"`
+	}{OTP: otp}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// otp bundles the identity and metadata issued for a single one-time
+// password, along with when it expires.
+type otp struct {
+	identity string
+	meta     map[string]any
+	expires  time.Time
+}
+
+// RetentionMap is a short-lived store for one-time passwords, keyed by the
+// OTP string. Entries are swept once their retention period elapses, so
+// callers should Verify an OTP shortly after issuing it.
+type RetentionMap struct {
+	mu        sync.Mutex
+	otps      map[string]otp
+	retention time.Duration
+}
+
+// NewRetentionMap creates a RetentionMap that expires entries after
+// retention and starts its background sweep goroutine.
+func NewRetentionMap(retention time.Duration) *RetentionMap {
+	rm := &RetentionMap{
+		otps:      make(map[string]otp),
+		retention: retention,
+	}
+	go rm.sweep(context.Background())
+	return rm
+}
+
+// Issue mints a new OTP for identity and stores meta alongside it until it
+// is verified or expires.
+func (rm *RetentionMap) Issue(identity string, meta map[string]any) string {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	token := uuid.NewString()
+	rm.otps[token] = otp{
+		identity: identity,
+		meta:     meta,
+		expires:  time.Now().Add(rm.retention),
+	}
+	return token
+}
+
+// Verify consumes token if it exists and has not expired, returning the
+// identity and metadata it was issued with. A token can only be verified
+// once.
+func (rm *RetentionMap) Verify(token string) (identity string, meta map[string]any, ok bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	entry, found := rm.otps[token]
+	if !found {
+		return "", nil, false
+	}
+	delete(rm.otps, token)
+
+	if time.Now().After(entry.expires) {
+		return "", nil, false
+	}
+	return entry.identity, entry.meta, true
+}
+
+// sweep periodically removes expired OTPs so the map does not grow
+// unbounded when clients request logins they never complete.
+func (rm *RetentionMap) sweep(ctx context.Context) {
+	ticker := time.NewTicker(rm.retention)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rm.mu.Lock()
+			for token, entry := range rm.otps {
+				if time.Now().After(entry.expires) {
+					delete(rm.otps, token)
+				}
+			}
+			rm.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package socky
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetentionMapIssueVerifyIsOneShot(t *testing.T) {
+	rm := NewRetentionMap(time.Second)
+	token := rm.Issue("user1", map[string]any{"role": "admin"})
+
+	identity, meta, ok := rm.Verify(token)
+	if !ok || identity != "user1" || meta["role"] != "admin" {
+		t.Fatalf("Verify = (%q, %v, %v), want (\"user1\", {role:admin}, true)", identity, meta, ok)
+	}
+
+	if _, _, ok := rm.Verify(token); ok {
+		t.Fatal("a token should not verify twice")
+	}
+}
+
+func TestRetentionMapExpiry(t *testing.T) {
+	rm := NewRetentionMap(10 * time.Millisecond)
+	token := rm.Issue("user1", nil)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, _, ok := rm.Verify(token); ok {
+		t.Fatal("an expired token should not verify")
+	}
+}
+
+func TestServeRejectsMissingOTP(t *testing.T) {
+	m := Socky()
+	m.SetAuthenticator(func(r *http.Request) (string, map[string]any, error) {
+		return "user1", nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	rec := httptest.NewRecorder()
+	m.Serve(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestLoginHandlerIssuesVerifiableOTP(t *testing.T) {
+	m := Socky()
+	m.SetAuthenticator(func(r *http.Request) (string, map[string]any, error) {
+		return "user1", nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	rec := httptest.NewRecorder()
+	m.LoginHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body struct {
+		OTP string `json:"otp"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	identity, _, ok := m.otps.Verify(body.OTP)
+	if !ok || identity != "user1" {
+		t.Fatalf("Verify = (%q, %v), want (\"user1\", true)", identity, ok)
+	}
+}
+
+func TestLoginHandlerWithoutAuthenticator(t *testing.T) {
+	m := Socky()
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	rec := httptest.NewRecorder()
+	m.LoginHandler(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
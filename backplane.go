@@ -0,0 +1,76 @@
+package socky
+
+// Backplane lets Broadcast/Roomcast traffic reach clients connected to
+// other Manager instances, e.g. behind a load balancer. Concrete
+// implementations (Redis, NATS, ...) live under socky/backplane so this
+// package stays free of any particular pub/sub client.
+type Backplane interface {
+	Publish(topic string, event Event) error
+	Subscribe(topic string, handler func(Event)) error
+}
+
+// broadcastTopic is the fixed topic BroadcastEvent publishes to and
+// subscribes on when a Backplane is configured.
+const broadcastTopic = "socky:broadcast"
+
+// roomTopic returns the backplane topic used for a given room name.
+func roomTopic(name string) string {
+	return "socky:room:" + name
+}
+
+// SetBackplane wires b as the cross-process transport for this Manager.
+// It subscribes to the broadcast topic and to every room already open on
+// this Manager; rooms opened afterwards are subscribed to lazily by
+// Manager.Room. Publishing loops are avoided by each Backplane
+// implementation tagging its own messages with an origin node ID and
+// dropping messages it published itself.
+func (m *Manager) SetBackplane(b Backplane) error {
+	m.mu.Lock()
+	m.backplane = b
+	m.mu.Unlock()
+
+	m.roomsMu.RLock()
+	rooms := make([]*Room, 0, len(m.rooms))
+	for _, room := range m.rooms {
+		rooms = append(rooms, room)
+	}
+	m.roomsMu.RUnlock()
+
+	if err := b.Subscribe(broadcastTopic, m.deliverBroadcast); err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		if err := b.Subscribe(roomTopic(room.name), room.Broadcast); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverBroadcast fans an inbound backplane broadcast event out to every
+// client connected to this node.
+func (m *Manager) deliverBroadcast(event Event) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.clients {
+		client.send(event)
+	}
+}
+
+// Publish sends event to every client in room on this node, and, when a
+// Backplane is configured, to every other node subscribed to room so
+// their own local members receive it too.
+func (m *Manager) Publish(room string, event Event) error {
+	r := m.Room(room)
+	r.Broadcast(event)
+
+	m.mu.RLock()
+	backplane := m.backplane
+	m.mu.RUnlock()
+
+	if backplane == nil {
+		return nil
+	}
+	return backplane.Publish(roomTopic(room), event)
+}
@@ -0,0 +1,34 @@
+// Package backplane provides concrete socky.Backplane implementations so
+// a Manager's broadcasts and roomcasts can reach clients connected to
+// other processes.
+package backplane
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/smarbo/socky"
+)
+
+// envelope is the wire format published by every implementation in this
+// package. NodeID lets a subscriber drop messages it published itself,
+// which is how loops are avoided when a node is both publisher and
+// subscriber on the same topic.
+type envelope struct {
+	NodeID string      `json:"nodeId"`
+	Event  socky.Event `json:"event"`
+}
+
+func marshalEnvelope(nodeID string, event socky.Event) ([]byte, error) {
+	return json.Marshal(envelope{NodeID: nodeID, Event: event})
+}
+
+func unmarshalEnvelope(data []byte) (envelope, error) {
+	var env envelope
+	err := json.Unmarshal(data, &env)
+	return env, err
+}
+
+func newNodeID() string {
+	return uuid.NewString()
+}
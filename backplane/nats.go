@@ -0,0 +1,51 @@
+package backplane
+
+import (
+	"log"
+
+	"github.com/nats-io/nats.go"
+	"github.com/smarbo/socky"
+)
+
+// NATSBackplane implements socky.Backplane on top of a NATS core
+// pub/sub subject per topic.
+type NATSBackplane struct {
+	conn   *nats.Conn
+	nodeID string
+}
+
+// NewNATSBackplane returns a Backplane that publishes and subscribes
+// through conn. Every instance is tagged with its own node ID so it can
+// ignore its own published messages when it is also a subscriber.
+func NewNATSBackplane(conn *nats.Conn) *NATSBackplane {
+	return &NATSBackplane{
+		conn:   conn,
+		nodeID: newNodeID(),
+	}
+}
+
+// Publish sends event to every NATSBackplane subscribed to topic.
+func (b *NATSBackplane) Publish(topic string, event socky.Event) error {
+	data, err := marshalEnvelope(b.nodeID, event)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(topic, data)
+}
+
+// Subscribe runs handler for every event published to topic by another
+// node.
+func (b *NATSBackplane) Subscribe(topic string, handler func(socky.Event)) error {
+	_, err := b.conn.Subscribe(topic, func(msg *nats.Msg) {
+		env, err := unmarshalEnvelope(msg.Data)
+		if err != nil {
+			log.Println("backplane: dropping malformed nats message: ", err)
+			return
+		}
+		if env.NodeID == b.nodeID {
+			return
+		}
+		handler(env.Event)
+	})
+	return err
+}
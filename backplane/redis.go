@@ -0,0 +1,60 @@
+package backplane
+
+import (
+	"context"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/smarbo/socky"
+)
+
+// RedisBackplane implements socky.Backplane on top of Redis Pub/Sub.
+type RedisBackplane struct {
+	client *redis.Client
+	nodeID string
+}
+
+// NewRedisBackplane returns a Backplane that publishes and subscribes
+// through client. Every instance is tagged with its own node ID so it can
+// ignore its own published messages when it is also a subscriber.
+func NewRedisBackplane(client *redis.Client) *RedisBackplane {
+	return &RedisBackplane{
+		client: client,
+		nodeID: newNodeID(),
+	}
+}
+
+// Publish sends event to every RedisBackplane subscribed to topic.
+func (b *RedisBackplane) Publish(topic string, event socky.Event) error {
+	data, err := marshalEnvelope(b.nodeID, event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(context.Background(), topic, data).Err()
+}
+
+// Subscribe runs handler for every event published to topic by another
+// node. Subscribe returns once the subscription is established; messages
+// are delivered on a background goroutine for the lifetime of b.
+func (b *RedisBackplane) Subscribe(topic string, handler func(socky.Event)) error {
+	sub := b.client.Subscribe(context.Background(), topic)
+	if _, err := sub.Receive(context.Background()); err != nil {
+		return err
+	}
+
+	go func() {
+		for msg := range sub.Channel() {
+			env, err := unmarshalEnvelope([]byte(msg.Payload))
+			if err != nil {
+				log.Println("backplane: dropping malformed redis message: ", err)
+				continue
+			}
+			if env.NodeID == b.nodeID {
+				continue
+			}
+			handler(env.Event)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package socky
+
+import "testing"
+
+// fakeBackplane mimics Redis/NATS pub/sub semantics: Publish fans out to
+// every Subscribe handler on the same topic, including the publisher's
+// own. Unlike backplane.RedisBackplane/NATSBackplane, it does not filter
+// out its own messages by origin.
+type fakeBackplane struct {
+	subs map[string][]func(Event)
+}
+
+func newFakeBackplane() *fakeBackplane {
+	return &fakeBackplane{subs: make(map[string][]func(Event))}
+}
+
+func (b *fakeBackplane) Publish(topic string, event Event) error {
+	for _, handler := range b.subs[topic] {
+		handler(event)
+	}
+	return nil
+}
+
+func (b *fakeBackplane) Subscribe(topic string, handler func(Event)) error {
+	b.subs[topic] = append(b.subs[topic], handler)
+	return nil
+}
+
+func TestPublishDeliversLocallyWithBackplaneConfigured(t *testing.T) {
+	m := Socky()
+	if err := m.SetBackplane(newFakeBackplane()); err != nil {
+		t.Fatalf("SetBackplane: %v", err)
+	}
+
+	client := &Client{manager: m, egress: make(chan Event, 1), rooms: make(map[string]bool)}
+	m.Room("lobby").Join(client)
+
+	event := Event{Type: "chat", From: "someone"}
+	if err := m.Publish("lobby", event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case got := <-client.egress:
+		if got.Type != event.Type {
+			t.Fatalf("got event type %q, want %q", got.Type, event.Type)
+		}
+	default:
+		t.Fatal("local room member did not receive the published event")
+	}
+}
@@ -0,0 +1,38 @@
+package socky
+
+import (
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+)
+
+// Codec controls how an Event is translated to and from the bytes sent
+// over a WebSocket connection. The default is JSONCodec; callers with
+// binary payloads (protobuf, MessagePack, ...) can install their own via
+// Manager.SetCodec.
+type Codec interface {
+	// Marshal encodes event for the wire, returning the payload and the
+	// gorilla/websocket message type (TextMessage or BinaryMessage) it
+	// should be sent as.
+	Marshal(event Event) (data []byte, messageType int, err error)
+	// Unmarshal decodes a message of the given gorilla/websocket message
+	// type back into an Event.
+	Unmarshal(messageType int, data []byte) (Event, error)
+}
+
+// JSONCodec is the default Codec, encoding Events as JSON text frames.
+type JSONCodec struct{}
+
+// Marshal encodes event as JSON, always as a TextMessage.
+func (JSONCodec) Marshal(event Event) ([]byte, int, error) {
+	data, err := json.Marshal(event)
+	return data, websocket.TextMessage, err
+}
+
+// Unmarshal decodes a JSON-encoded Event. It accepts both text and binary
+// frames, since some clients send JSON as binary.
+func (JSONCodec) Unmarshal(messageType int, data []byte) (Event, error) {
+	var event Event
+	err := json.Unmarshal(data, &event)
+	return event, err
+}
@@ -0,0 +1,27 @@
+// Package codec provides concrete socky.Codec implementations that pull
+// in an external encoding library, so the core socky package stays free
+// of any particular wire format beyond its JSONCodec default.
+package codec
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/smarbo/socky"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackCodec encodes Events as MessagePack, sent as binary frames.
+type MsgpackCodec struct{}
+
+// Marshal encodes event as MessagePack, always as a BinaryMessage.
+func (MsgpackCodec) Marshal(event socky.Event) ([]byte, int, error) {
+	data, err := msgpack.Marshal(event)
+	return data, websocket.BinaryMessage, err
+}
+
+// Unmarshal decodes a MessagePack-encoded Event. It accepts both binary
+// and text frames, since some clients send MessagePack as text.
+func (MsgpackCodec) Unmarshal(messageType int, data []byte) (socky.Event, error) {
+	var event socky.Event
+	err := msgpack.Unmarshal(data, &event)
+	return event, err
+}
@@ -0,0 +1,60 @@
+package socky
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	var codec JSONCodec
+	event := Event{Type: "chat", Payload: []byte(`{"msg":"hi"}`), From: "alice"}
+
+	data, messageType, err := codec.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if messageType != websocket.TextMessage {
+		t.Fatalf("messageType = %d, want TextMessage", messageType)
+	}
+
+	got, err := codec.Unmarshal(messageType, data)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Type != event.Type || got.From != event.From || string(got.Payload) != string(event.Payload) {
+		t.Fatalf("got %+v, want %+v", got, event)
+	}
+}
+
+func TestManagerDefaultsToJSONCodec(t *testing.T) {
+	m := Socky()
+	if _, ok := m.getCodec().(JSONCodec); !ok {
+		t.Fatalf("default codec = %T, want JSONCodec", m.getCodec())
+	}
+}
+
+// fakeCodec is a minimal Codec used only to prove SetCodec actually swaps
+// the Manager's encoding rather than just re-wrapping JSONCodec.
+type fakeCodec struct{}
+
+func (fakeCodec) Marshal(event Event) ([]byte, int, error) {
+	return []byte("fake"), websocket.BinaryMessage, nil
+}
+
+func (fakeCodec) Unmarshal(messageType int, data []byte) (Event, error) {
+	return Event{Type: "fake"}, nil
+}
+
+func TestSetCodecOverridesDefault(t *testing.T) {
+	m := Socky()
+	m.SetCodec(fakeCodec{})
+
+	data, messageType, err := m.getCodec().Marshal(Event{Type: "chat"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if messageType != websocket.BinaryMessage || string(data) != "fake" {
+		t.Fatalf("got (%q, %d), want (\"fake\", BinaryMessage) - SetCodec did not take effect", data, messageType)
+	}
+}
@@ -0,0 +1,53 @@
+package socky
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Middleware wraps an EventHandler to add cross-cutting behaviour
+// (logging, panic recovery, rate limiting, auth, ...) around every
+// handler registered through AddEventHandler.
+type Middleware func(EventHandler) EventHandler
+
+// Use appends mw to the manager's middleware chain. Middlewares run in
+// the order they were added, outermost first, around every handler
+// dispatched by routeEvent.
+func (m *Manager) Use(mw ...Middleware) {
+  m.middleware = append(m.middleware, mw...)
+}
+
+// wrap applies the middleware chain to handler, outermost first.
+func (m *Manager) wrap(handler EventHandler) EventHandler {
+  for i := len(m.middleware) - 1; i >= 0; i-- {
+    handler = m.middleware[i](handler)
+  }
+  return handler
+}
+
+// RecoverMiddleware catches panics raised by the wrapped handler and
+// turns them into an error, so a single bad handler cannot kill the
+// Client's readMessages goroutine.
+func RecoverMiddleware(next EventHandler) EventHandler {
+  return func(event Event, c *Client) (err error) {
+    defer func() {
+      if r := recover(); r != nil {
+        log.Printf("socky: recovered panic in handler for %q: %v", event.Type, r)
+        err = fmt.Errorf("handler panicked: %v", r)
+      }
+    }()
+    return next(event, c)
+  }
+}
+
+// LoggingMiddleware logs every event dispatched through it, along with how
+// long the wrapped handler took and whether it returned an error.
+func LoggingMiddleware(next EventHandler) EventHandler {
+  return func(event Event, c *Client) error {
+    start := time.Now()
+    err := next(event, c)
+    log.Printf("socky: handled %q for client %s in %s (err=%v)", event.Type, c.id, time.Since(start), err)
+    return err
+  }
+}
@@ -0,0 +1,95 @@
+package socky
+
+import "testing"
+
+func TestMiddlewareChainRunsOutermostFirst(t *testing.T) {
+	m := Socky()
+	var order []string
+
+	trace := func(name string) Middleware {
+		return func(next EventHandler) EventHandler {
+			return func(event Event, c *Client) error {
+				order = append(order, name)
+				return next(event, c)
+			}
+		}
+	}
+	m.Use(trace("first"), trace("second"))
+	m.AddEventHandler("ping", func(event Event, c *Client) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	c := newTestClient(m)
+	if err := m.routeEvent(Event{Type: "ping"}, c); err != nil {
+		t.Fatalf("routeEvent: %v", err)
+	}
+
+	want := []string{"first", "second", "handler"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRecoverMiddlewareCatchesPanic(t *testing.T) {
+	m := Socky()
+	m.Use(RecoverMiddleware)
+	m.AddEventHandler("boom", func(event Event, c *Client) error {
+		panic("kaboom")
+	})
+
+	c := newTestClient(m)
+	if err := m.routeEvent(Event{Type: "boom"}, c); err == nil {
+		t.Fatal("expected an error recovered from the handler's panic")
+	}
+}
+
+func TestRateLimitMiddlewareRejectsOverLimit(t *testing.T) {
+	m := Socky()
+	m.Use(RateLimitMiddleware(1))
+	m.AddEventHandler("ping", func(event Event, c *Client) error { return nil })
+
+	c := newTestClient(m)
+	if err := m.routeEvent(Event{Type: "ping"}, c); err != nil {
+		t.Fatalf("first event: %v", err)
+	}
+	if err := m.routeEvent(Event{Type: "ping"}, c); err == nil {
+		t.Fatal("a second immediate event should be rate limited")
+	}
+}
+
+// TestRateLimitMiddlewareFreesLimiterOnDisconnect exercises the internal
+// disconnect hook RateLimitMiddleware registers via Manager.onDisconnect,
+// simulating what RemoveClient runs on disconnect. If the limiter for c
+// wasn't freed, the assertions below would still see it rate limited.
+func TestRateLimitMiddlewareFreesLimiterOnDisconnect(t *testing.T) {
+	m := Socky()
+	m.Use(RateLimitMiddleware(1))
+	m.AddEventHandler("ping", func(event Event, c *Client) error { return nil })
+
+	c := newTestClient(m)
+	if err := m.routeEvent(Event{Type: "ping"}, c); err != nil {
+		t.Fatalf("routeEvent: %v", err)
+	}
+
+	m.mu.RLock()
+	hooks := append([]ConnectionHandler(nil), m.disconnectHooks...)
+	m.mu.RUnlock()
+	if len(hooks) == 0 {
+		t.Fatal("RateLimitMiddleware should have registered a disconnect hook")
+	}
+	for _, hook := range hooks {
+		if err := hook(c); err != nil {
+			t.Fatalf("disconnect hook: %v", err)
+		}
+	}
+
+	if err := m.routeEvent(Event{Type: "ping"}, c); err != nil {
+		t.Fatalf("event after the limiter was freed should not be rate limited: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package socky
+
+import "time"
+
+// SlowClientPolicy controls what a Client does when its egress buffer is
+// full and another Event needs to be queued for it.
+type SlowClientPolicy int
+
+const (
+	// DropNewest discards the Event that was about to be queued, leaving
+	// the existing buffer untouched. It is the zero value so that an
+	// Options with no SlowClientPolicy set behaves like defaultOptions.
+	DropNewest SlowClientPolicy = iota
+	// DropOldest discards the oldest queued Event to make room for the
+	// new one.
+	DropOldest
+	// Disconnect drops the Client rather than let it fall further behind.
+	Disconnect
+)
+
+// Options configures a Manager's send-path behaviour: how much a Client
+// may buffer, what happens once that buffer is full, and the limits
+// applied to the underlying WebSocket connection.
+type Options struct {
+	// EgressBuffer is the size of each Client's egress channel.
+	EgressBuffer int
+	// SlowClientPolicy decides what happens when a Client's egress buffer
+	// is full.
+	SlowClientPolicy SlowClientPolicy
+	// WriteWait is the deadline given to each WebSocket write.
+	WriteWait time.Duration
+	// MaxMessageSize is the maximum size, in bytes, of an inbound message.
+	MaxMessageSize int64
+}
+
+func defaultOptions() Options {
+	return Options{
+		EgressBuffer:     16,
+		SlowClientPolicy: DropNewest,
+		WriteWait:        10 * time.Second,
+		MaxMessageSize:   512,
+	}
+}
+
+// withDefaults overlays opts onto defaultOptions, so a caller that only
+// sets a subset of fields doesn't silently zero out the rest (e.g. an
+// unset WriteWait would otherwise make every WebSocket write deadline
+// already-expired). This works uniformly across every field, including
+// SlowClientPolicy, because DropNewest - the actual default - is its zero
+// value.
+func (opts Options) withDefaults() Options {
+	merged := defaultOptions()
+	if opts.SlowClientPolicy != 0 {
+		merged.SlowClientPolicy = opts.SlowClientPolicy
+	}
+	if opts.EgressBuffer != 0 {
+		merged.EgressBuffer = opts.EgressBuffer
+	}
+	if opts.WriteWait != 0 {
+		merged.WriteWait = opts.WriteWait
+	}
+	if opts.MaxMessageSize != 0 {
+		merged.MaxMessageSize = opts.MaxMessageSize
+	}
+	return merged
+}
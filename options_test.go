@@ -0,0 +1,106 @@
+package socky
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSlowClientPolicyDropOldestKeepsNewestEvent(t *testing.T) {
+	m := SockyWithOptions(Options{SlowClientPolicy: DropOldest, EgressBuffer: 1})
+	c := &Client{manager: m, egress: make(chan Event, 1), rooms: make(map[string]bool)}
+
+	c.send(Event{Type: "old"})
+	c.send(Event{Type: "new"})
+
+	if got := <-c.egress; got.Type != "new" {
+		t.Fatalf("got %q, want %q", got.Type, "new")
+	}
+}
+
+func TestSlowClientPolicyDropNewestKeepsOldestEvent(t *testing.T) {
+	m := SockyWithOptions(Options{SlowClientPolicy: DropNewest, EgressBuffer: 1})
+	c := &Client{manager: m, egress: make(chan Event, 1), rooms: make(map[string]bool)}
+
+	c.send(Event{Type: "old"})
+	c.send(Event{Type: "new"})
+
+	if got := <-c.egress; got.Type != "old" {
+		t.Fatalf("got %q, want %q", got.Type, "old")
+	}
+	if stats := c.Stats(); stats.Dropped != 1 {
+		t.Fatalf("Dropped = %d, want 1", stats.Dropped)
+	}
+}
+
+func TestSockyWithOptionsDefaultsUnsetFields(t *testing.T) {
+	m := SockyWithOptions(Options{EgressBuffer: 4})
+
+	want := defaultOptions()
+	if m.options.SlowClientPolicy != want.SlowClientPolicy {
+		t.Fatalf("SlowClientPolicy = %v, want %v", m.options.SlowClientPolicy, want.SlowClientPolicy)
+	}
+	if m.options.WriteWait != want.WriteWait {
+		t.Fatalf("WriteWait = %v, want %v", m.options.WriteWait, want.WriteWait)
+	}
+	if m.options.MaxMessageSize != want.MaxMessageSize {
+		t.Fatalf("MaxMessageSize = %d, want %d", m.options.MaxMessageSize, want.MaxMessageSize)
+	}
+	if m.options.EgressBuffer != 4 {
+		t.Fatalf("EgressBuffer = %d, want 4", m.options.EgressBuffer)
+	}
+}
+
+// TestDisconnectPolicyDoesNotDeadlockBroadcast reproduces the scenario a
+// reviewer caught by inspection: Room.Broadcast holds room.mu.RLock()
+// while it calls Client.send for each member, and under SlowClientPolicy
+// Disconnect a full egress buffer used to call Manager.RemoveClient
+// synchronously from there, which re-enters room.mu via Room.Leave in the
+// same goroutine and hangs forever. It must complete well within the
+// timeout.
+func TestDisconnectPolicyDoesNotDeadlockBroadcast(t *testing.T) {
+	m := SockyWithOptions(Options{SlowClientPolicy: Disconnect, EgressBuffer: 1})
+
+	var upgrader websocket.Upgrader
+	serverConn := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		serverConn <- conn
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	clientConn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer clientConn.Close()
+
+	c := newClient(<-serverConn, m)
+	m.AddClient(c)
+
+	room := m.Room("lobby")
+	room.Join(c)
+
+	c.egress <- Event{Type: "filler"} // fills EgressBuffer: 1 so the next send overflows
+
+	done := make(chan struct{})
+	go func() {
+		room.Broadcast(Event{Type: "overflow"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Broadcast under SlowClientPolicy Disconnect deadlocked")
+	}
+}
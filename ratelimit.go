@@ -0,0 +1,56 @@
+package socky
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitMiddleware rejects events once a client exceeds perClient
+// events/sec, tracked independently per Client. It registers a cleanup
+// hook via Manager.onDisconnect the first time it sees that Manager, so a
+// client's limiter is freed when the client disconnects rather than
+// leaking for the life of the process. This is a separate hook list from
+// the public OnDisconnect field, so assigning OnDisconnect later cannot
+// clobber it.
+func RateLimitMiddleware(perClient rate.Limit) Middleware {
+  var mu sync.Mutex
+  limiters := make(map[*Client]*rate.Limiter)
+
+  // hooked records the Managers a cleanup hook has been registered with.
+  hooked := make(map[*Manager]bool)
+
+  burst := int(perClient)
+  if burst < 1 {
+    burst = 1
+  }
+
+  return func(next EventHandler) EventHandler {
+    return func(event Event, c *Client) error {
+      mu.Lock()
+      register := !hooked[c.manager]
+      hooked[c.manager] = true
+      limiter, ok := limiters[c]
+      if !ok {
+        limiter = rate.NewLimiter(perClient, burst)
+        limiters[c] = limiter
+      }
+      mu.Unlock()
+
+      if register {
+        c.manager.onDisconnect(func(c *Client) error {
+          mu.Lock()
+          delete(limiters, c)
+          mu.Unlock()
+          return nil
+        })
+      }
+
+      if !limiter.Allow() {
+        return fmt.Errorf("rate limit exceeded for event %q", event.Type)
+      }
+      return next(event, c)
+    }
+  }
+}
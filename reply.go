@@ -0,0 +1,125 @@
+package socky
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// pendingRequest is a single in-flight Request awaiting a correlated
+// reply, tracked on Manager.pending.
+type pendingRequest struct {
+	client *Client
+	ch     chan Event
+}
+
+// Reply sends payload back to the client that sent original, correlated
+// via Event.ID/ReplyTo. It gives EventHandlers a Socket.IO-like ack on top
+// of the fire-and-forget SendEvent.
+func (c *Client) Reply(original Event, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling reply payload: %w", err)
+	}
+
+	c.SendEvent(Event{
+		Type:    original.Type,
+		Payload: data,
+		From:    c.id,
+		ID:      uuid.NewString(),
+		ReplyTo: original.ID,
+	})
+	return nil
+}
+
+// ReplyHandler is like an EventHandler, but instead of writing a reply
+// itself via Client.Reply, it returns the value to send back. A nil
+// reply with a nil error sends no reply at all.
+type ReplyHandler func(event Event, c *Client) (reply any, err error)
+
+// AddReplyHandler registers handler for msgType, wrapping it so its
+// returned value is auto-serialized and sent back via Client.Reply,
+// correlated to event by Event.ID/ReplyTo. Use AddEventHandler instead
+// for handlers that manage their own replies, or that don't reply.
+func (m *Manager) AddReplyHandler(msgType string, handler ReplyHandler) {
+	m.AddEventHandler(msgType, func(event Event, c *Client) error {
+		reply, err := handler(event, c)
+		if err != nil {
+			return err
+		}
+		if reply == nil {
+			return nil
+		}
+		return c.Reply(event, reply)
+	})
+}
+
+// Request sends event to c, assigning it an ID if it does not already
+// have one, and blocks until a correlated reply arrives or ctx is done.
+func (c *Client) Request(ctx context.Context, event Event) (Event, error) {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+
+	ch := c.manager.awaitReply(c, event.ID)
+	defer c.manager.cancelReply(event.ID)
+
+	c.SendEvent(event)
+
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	}
+}
+
+// awaitReply registers a channel that will receive the reply to
+// requestID, once seen by routeEvent.
+func (m *Manager) awaitReply(c *Client, requestID string) chan Event {
+	ch := make(chan Event, 1)
+
+	m.pendingMu.Lock()
+	m.pending[requestID] = pendingRequest{client: c, ch: ch}
+	m.pendingMu.Unlock()
+
+	return ch
+}
+
+// resolveReply delivers event to the Request call awaiting it, if any.
+func (m *Manager) resolveReply(event Event) {
+	m.pendingMu.Lock()
+	req, ok := m.pending[event.ReplyTo]
+	if ok {
+		delete(m.pending, event.ReplyTo)
+	}
+	m.pendingMu.Unlock()
+
+	if ok {
+		req.ch <- event
+	}
+}
+
+// cancelReply removes requestID from the pending table without delivering
+// a reply, e.g. once its Request call has returned via ctx cancellation.
+func (m *Manager) cancelReply(requestID string) {
+	m.pendingMu.Lock()
+	delete(m.pending, requestID)
+	m.pendingMu.Unlock()
+}
+
+// cancelPendingFor drops every request awaiting a reply from client so a
+// disconnect does not leak pending table entries. Request callers still
+// unblock via their own ctx, since no reply is delivered here.
+func (m *Manager) cancelPendingFor(client *Client) {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+
+	for id, req := range m.pending {
+		if req.client == client {
+			delete(m.pending, id)
+		}
+	}
+}
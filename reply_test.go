@@ -0,0 +1,130 @@
+package socky
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestReplyCorrelatesToOriginalEvent(t *testing.T) {
+	m := Socky()
+	c := newTestClient(m)
+
+	original := Event{Type: "whoami", ID: "req-1"}
+	if err := c.Reply(original, "me"); err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+
+	sent := <-c.egress
+	if sent.ReplyTo != original.ID {
+		t.Fatalf("ReplyTo = %q, want %q", sent.ReplyTo, original.ID)
+	}
+	if string(sent.Payload) != `"me"` {
+		t.Fatalf("Payload = %s, want %q", sent.Payload, `"me"`)
+	}
+}
+
+func TestRequestResolvesOnCorrelatedReply(t *testing.T) {
+	m := Socky()
+	c := newTestClient(m)
+
+	go func() {
+		sent := <-c.egress
+		reply := Event{Type: sent.Type, ReplyTo: sent.ID, Payload: json.RawMessage(`"pong"`)}
+		if err := m.routeEvent(reply, c); err != nil {
+			t.Errorf("routeEvent: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	got, err := c.Request(ctx, Event{Type: "ping"})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if string(got.Payload) != `"pong"` {
+		t.Fatalf("Payload = %s, want %q", got.Payload, `"pong"`)
+	}
+}
+
+func TestRequestTimesOutAndClearsPendingEntry(t *testing.T) {
+	m := Socky()
+	c := newTestClient(m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Request(ctx, Event{Type: "ping"}); err == nil {
+		t.Fatal("Request should have timed out")
+	}
+
+	m.pendingMu.Lock()
+	n := len(m.pending)
+	m.pendingMu.Unlock()
+	if n != 0 {
+		t.Fatalf("pending table should be empty after a timed-out Request, got %d entries", n)
+	}
+}
+
+func TestCancelPendingForDropsEntriesOnDisconnect(t *testing.T) {
+	m := Socky()
+	c := newTestClient(m)
+
+	ch := m.awaitReply(c, "req-1")
+	m.cancelPendingFor(c)
+
+	select {
+	case <-ch:
+		t.Fatal("no reply should be delivered once cancelPendingFor has run")
+	default:
+	}
+
+	m.pendingMu.Lock()
+	_, ok := m.pending["req-1"]
+	m.pendingMu.Unlock()
+	if ok {
+		t.Fatal("pending entry should have been removed")
+	}
+}
+
+func TestAddReplyHandlerAutoRepliesWithReturnedValue(t *testing.T) {
+	m := Socky()
+	m.AddReplyHandler("whoami", func(event Event, c *Client) (any, error) {
+		return "me", nil
+	})
+
+	c := newTestClient(m)
+	event := Event{Type: "whoami", ID: "req-1"}
+
+	if err := m.routeEvent(event, c); err != nil {
+		t.Fatalf("routeEvent: %v", err)
+	}
+
+	reply := <-c.egress
+	if reply.ReplyTo != event.ID {
+		t.Fatalf("ReplyTo = %q, want %q", reply.ReplyTo, event.ID)
+	}
+	if string(reply.Payload) != `"me"` {
+		t.Fatalf("Payload = %s, want %q", reply.Payload, `"me"`)
+	}
+}
+
+func TestAddReplyHandlerSendsNoReplyForNilValue(t *testing.T) {
+	m := Socky()
+	m.AddReplyHandler("ack", func(event Event, c *Client) (any, error) {
+		return nil, nil
+	})
+
+	c := newTestClient(m)
+	if err := m.routeEvent(Event{Type: "ack", ID: "req-1"}, c); err != nil {
+		t.Fatalf("routeEvent: %v", err)
+	}
+
+	select {
+	case got := <-c.egress:
+		t.Fatalf("expected no reply, got %+v", got)
+	default:
+	}
+}
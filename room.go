@@ -0,0 +1,144 @@
+package socky
+
+import (
+	"log"
+	"sync"
+)
+
+// RoomHandler is a handler for room membership events, example: when a
+// client joins or leaves a Room.
+type RoomHandler func(c *Client, room *Room) error
+
+// Room is a named group of clients that can be broadcast to together.
+// Rooms are owned by a Manager and are safe for concurrent use; a Client
+// may be a member of any number of Rooms at once.
+type Room struct {
+	name    string
+	manager *Manager
+
+	mu      sync.RWMutex
+	members ClientList
+}
+
+func newRoom(manager *Manager, name string) *Room {
+	return &Room{
+		name:    name,
+		manager: manager,
+		members: make(ClientList),
+	}
+}
+
+// Name returns the room's name.
+func (r *Room) Name() string {
+	return r.name
+}
+
+// Join adds c to the room and fires the manager's OnJoin callback, if any.
+// Joining a room a client already belongs to is a no-op.
+func (r *Room) Join(c *Client) {
+	r.mu.Lock()
+	if r.members[c] {
+		r.mu.Unlock()
+		return
+	}
+	r.members[c] = true
+	r.mu.Unlock()
+
+	c.roomsMu.Lock()
+	c.rooms[r.name] = true
+	c.roomsMu.Unlock()
+
+	if c.manager.OnJoin != nil {
+		c.manager.OnJoin(c, r)
+	}
+}
+
+// Leave removes c from the room and fires the manager's OnLeave callback,
+// if any. Leaving a room a client does not belong to is a no-op. Once the
+// last member leaves, the Room is pruned from the Manager so it does not
+// accumulate for the life of the process across session/per-document
+// rooms that come and go.
+func (r *Room) Leave(c *Client) {
+	r.mu.Lock()
+	if !r.members[c] {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.members, c)
+	empty := len(r.members) == 0
+	r.mu.Unlock()
+
+	c.roomsMu.Lock()
+	delete(c.rooms, r.name)
+	c.roomsMu.Unlock()
+
+	if c.manager.OnLeave != nil {
+		c.manager.OnLeave(c, r)
+	}
+
+	if empty {
+		r.manager.pruneRoom(r)
+	}
+}
+
+// Broadcast sends event to every member of the room.
+func (r *Room) Broadcast(event Event) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for member := range r.members {
+		member.send(event)
+	}
+}
+
+// Members returns the clients currently in the room.
+func (r *Room) Members() []*Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]*Client, 0, len(r.members))
+	for member := range r.members {
+		members = append(members, member)
+	}
+	return members
+}
+
+// Room returns the Room named name, creating it if it does not already
+// exist. The returned Room is owned and tracked by the Manager.
+func (m *Manager) Room(name string) *Room {
+	m.roomsMu.Lock()
+	if room, ok := m.rooms[name]; ok {
+		m.roomsMu.Unlock()
+		return room
+	}
+	room := newRoom(m, name)
+	m.rooms[name] = room
+	m.roomsMu.Unlock()
+
+	m.mu.RLock()
+	backplane := m.backplane
+	m.mu.RUnlock()
+
+	if backplane != nil {
+		if err := backplane.Subscribe(roomTopic(name), room.Broadcast); err != nil {
+			log.Println("backplane subscribe error: ", err)
+		}
+	}
+	return room
+}
+
+// pruneRoom removes room from m.rooms if it is both still empty and still
+// the Room tracked under that name, so a Join racing right after the last
+// Leave isn't undone, and rejoining the name later starts a fresh Room.
+func (m *Manager) pruneRoom(room *Room) {
+	m.roomsMu.Lock()
+	defer m.roomsMu.Unlock()
+
+	if m.rooms[room.name] != room {
+		return
+	}
+	if len(room.Members()) != 0 {
+		return
+	}
+	delete(m.rooms, room.name)
+}
@@ -0,0 +1,89 @@
+package socky
+
+import "testing"
+
+func newTestClient(m *Manager) *Client {
+	return &Client{manager: m, egress: make(chan Event, 4), rooms: make(map[string]bool)}
+}
+
+func TestRoomJoinLeaveMultiMembership(t *testing.T) {
+	m := Socky()
+	c := newTestClient(m)
+
+	m.Room("a").Join(c)
+	m.Room("b").Join(c)
+
+	if !c.InRoom("a") || !c.InRoom("b") {
+		t.Fatal("client should be a member of both rooms")
+	}
+	if got := len(c.Rooms()); got != 2 {
+		t.Fatalf("len(Rooms()) = %d, want 2", got)
+	}
+
+	m.Room("a").Leave(c)
+	if c.InRoom("a") {
+		t.Fatal("client should have left room a")
+	}
+	if !c.InRoom("b") {
+		t.Fatal("client should still be a member of room b")
+	}
+}
+
+func TestRoomBroadcastReachesEveryMember(t *testing.T) {
+	m := Socky()
+	c1, c2 := newTestClient(m), newTestClient(m)
+
+	room := m.Room("lobby")
+	room.Join(c1)
+	room.Join(c2)
+
+	room.Broadcast(Event{Type: "hi"})
+
+	for _, c := range []*Client{c1, c2} {
+		select {
+		case got := <-c.egress:
+			if got.Type != "hi" {
+				t.Fatalf("got event type %q, want %q", got.Type, "hi")
+			}
+		default:
+			t.Fatal("room member did not receive the broadcast")
+		}
+	}
+}
+
+func TestLeavingLastMemberPrunesTheRoom(t *testing.T) {
+	m := Socky()
+	c := newTestClient(m)
+
+	room := m.Room("lobby")
+	room.Join(c)
+	room.Leave(c)
+
+	m.roomsMu.RLock()
+	_, stillTracked := m.rooms["lobby"]
+	m.roomsMu.RUnlock()
+	if stillTracked {
+		t.Fatal("an empty room should be pruned from Manager.rooms")
+	}
+
+	if fresh := m.Room("lobby"); fresh == room {
+		t.Fatal("rejoining a pruned room name should create a fresh Room, not reuse the old one")
+	}
+}
+
+func TestLeavingWhileStillOccupiedKeepsTheRoom(t *testing.T) {
+	m := Socky()
+	c1, c2 := newTestClient(m), newTestClient(m)
+
+	room := m.Room("lobby")
+	room.Join(c1)
+	room.Join(c2)
+	room.Leave(c1)
+
+	m.roomsMu.RLock()
+	_, stillTracked := m.rooms["lobby"]
+	m.roomsMu.RUnlock()
+	if !stillTracked {
+		t.Fatal("a room with a remaining member should not be pruned")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,13 @@ type Event struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 	From    string          `json:"from"`
+	// ID, when set, uniquely identifies this Event so a reply can be
+	// correlated back to it. See Client.Reply and Client.Request.
+	ID      string          `json:"id,omitempty"`
+	// ReplyTo holds the ID of the Event this one answers. Events with a
+	// non-empty ReplyTo are routed to the waiting Request call instead of
+	// the normal handler dispatch.
+	ReplyTo string          `json:"replyTo,omitempty"`
 }
 
 // EventHandler is a handler for socket message related events, example: when the frontend calls 'send_message' to the server
@@ -33,47 +41,138 @@ type Client struct {
   // manager is the pointer to the socket manager
   // which contains all clients allowing for broadcasts
 	manager    *Manager
-  // egress is the channel of events which recieves 
+  // egress is the channel of events which recieves
   // events and allows for sending events
 	egress     chan Event
-  // room is the room name of which the socket is currently connected to
-	room       string
+  // dropped counts Events discarded for this client under
+  // SlowClientPolicy DropOldest/DropNewest. Read via Stats.
+  dropped    uint64
+  // rooms is the set of room names the client currently belongs to,
+  // guarded by roomsMu. A client may belong to more than one room.
+  rooms      map[string]bool
+  roomsMu    sync.RWMutex
   // id is a unique id for the client
   // uses google UUID generator package
   id string
+  // Identity is the authenticated identity of the client, as returned by
+  // the Manager's Authenticator. It is empty when no Authenticator is
+  // configured. EventHandlers should trust this over the client-supplied
+  // Event.From, which is not verified.
+  Identity string
 }
 
 func newClient(conn *websocket.Conn, manager *Manager) *Client {
   newID := uuid.New()
 	return &Client{
-		conn,
-		manager,
-		make(chan Event),
-		"default",
-    newID.String(),
+		connection: conn,
+		manager:    manager,
+		egress:     make(chan Event, manager.options.EgressBuffer),
+		rooms:      make(map[string]bool),
+		id:         newID.String(),
 	}
 }
 
+// ClientStats reports a Client's send-path health, as returned by Stats.
+type ClientStats struct {
+	// Dropped is the number of Events discarded for this client under
+	// SlowClientPolicy DropOldest/DropNewest.
+	Dropped uint64
+	// QueueDepth is the number of Events currently buffered in egress.
+	QueueDepth int
+}
+
+// Stats reports c's current egress queue depth and drop count.
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		Dropped:    atomic.LoadUint64(&c.dropped),
+		QueueDepth: len(c.egress),
+	}
+}
+
+// Rooms returns the names of the rooms client currently belongs to.
+func (c *Client) Rooms() []string {
+	c.roomsMu.RLock()
+	defer c.roomsMu.RUnlock()
+
+	names := make([]string, 0, len(c.rooms))
+	for name := range c.rooms {
+		names = append(names, name)
+	}
+	return names
+}
+
+// InRoom reports whether client is currently a member of room.
+func (c *Client) InRoom(room string) bool {
+	c.roomsMu.RLock()
+	defer c.roomsMu.RUnlock()
+	return c.rooms[room]
+}
+
 var (
 	pongWait     = 10 * time.Second
 	pingInterval = (pongWait * 9) / 10
 )
 
-// SendEvent pushes an Event into the egress channel of the Client.
+// SendEvent pushes an Event into the egress channel of the Client,
+// applying the Manager's SlowClientPolicy if the channel is full.
 func (c *Client) SendEvent(event Event) {
-  c.egress <- event;
+  c.send(event)
+}
+
+// send is the single non-blocking entry point onto egress, so one slow
+// client can never stall a broadcast loop. If egress is full, it applies
+// c.manager.options.SlowClientPolicy.
+func (c *Client) send(event Event) {
+  select {
+  case c.egress <- event:
+    return
+  default:
+  }
+
+  switch c.manager.options.SlowClientPolicy {
+  case DropOldest:
+    select {
+    case <-c.egress:
+      atomic.AddUint64(&c.dropped, 1)
+    default:
+    }
+    select {
+    case c.egress <- event:
+    default:
+      atomic.AddUint64(&c.dropped, 1)
+    }
+  case Disconnect:
+    // RemoveClient takes m.mu and each of c's rooms' mu, which may
+    // already be read-locked by an ancestor Broadcast/deliverBroadcast
+    // frame in this same goroutine; calling it synchronously here would
+    // self-deadlock. Run it on its own goroutine instead.
+    go c.manager.RemoveClient(c)
+  default: // DropNewest
+    atomic.AddUint64(&c.dropped, 1)
+  }
 }
 
 func (c *Client) BroadcastEvent(event Event) {
-  for wsclient := range c.manager.clients {
-    wsclient.egress <- event;
+  c.manager.deliverBroadcast(event)
+
+  c.manager.mu.RLock()
+  backplane := c.manager.backplane
+  c.manager.mu.RUnlock()
+
+  if backplane != nil {
+    if err := backplane.Publish(broadcastTopic, event); err != nil {
+      log.Println("backplane publish error: ", err)
+    }
   }
-} 
+}
 
+// RoomcastEvent publishes event to every room c belongs to, so each member
+// of those rooms receives it, c included; a client sharing several rooms
+// with c receives it once per shared room. See Manager.Publish.
 func (c *Client) RoomcastEvent(event Event) {
-  for wsclient := range c.manager.clients {
-    if wsclient.room == c.room {
-      wsclient.egress <- event;
+  for _, name := range c.Rooms() {
+    if err := c.manager.Publish(name, event); err != nil {
+      log.Println("backplane publish error: ", err)
     }
   }
 }
@@ -88,11 +187,11 @@ func (c *Client) readMessages() {
 		log.Println(err)
 	}
 
-	c.connection.SetReadLimit(512)
+	c.connection.SetReadLimit(c.manager.options.MaxMessageSize)
 	c.connection.SetPongHandler(c.pongHandler)
 
 	for {
-		_, payload, err := c.connection.ReadMessage()
+		messageType, payload, err := c.connection.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Println(err)
@@ -100,10 +199,9 @@ func (c *Client) readMessages() {
 			break
 		}
 
-		var request Event
-
-		if err := json.Unmarshal(payload, &request); err != nil {
-			log.Printf("error marshalling event: %v", err)
+		request, err := c.manager.getCodec().Unmarshal(messageType, payload)
+		if err != nil {
+			log.Printf("error decoding event: %v", err)
 			break
 		}
 
@@ -130,18 +228,26 @@ func (c *Client) writeMessages() {
 				return
 			}
 
-			data, err := json.Marshal(message)
+			data, messageType, err := c.manager.getCodec().Marshal(message)
 			if err != nil {
 				log.Println(err)
 				return
 			}
 
-			if err := c.connection.WriteMessage(websocket.TextMessage, data); err != nil {
+			if err := c.connection.SetWriteDeadline(time.Now().Add(c.manager.options.WriteWait)); err != nil {
+				log.Println(err)
+				return
+			}
+			if err := c.connection.WriteMessage(messageType, data); err != nil {
 				log.Printf("failed to send message: %v", err)
 			}
 			log.Println("msg")
 
 		case <-ticker.C:
+			if err := c.connection.SetWriteDeadline(time.Now().Add(c.manager.options.WriteWait)); err != nil {
+				log.Println(err)
+				return
+			}
 			if err := c.connection.WriteMessage(websocket.PingMessage, []byte(``)); err != nil {
 				log.Println("writemsg error: ", err)
 				return
@@ -163,14 +269,88 @@ type Manager struct {
 	clients ClientList
 	mu sync.RWMutex
 
+  // options configures egress buffering, backpressure and WebSocket
+  // limits. Set at construction via SockyWithOptions.
+  options Options
+
   OnConnect ConnectionHandler
   OnDisconnect ConnectionHandler
+  // OnJoin and OnLeave, when set, fire whenever a client joins or leaves
+  // a Room, including via the default EventJoinRoom/EventLeaveRoom/
+  // EventChangeRoom handlers.
+  OnJoin RoomHandler
+  OnLeave RoomHandler
 	handlers map[string]EventHandler
+  // middleware is the chain wrapping every handler dispatched through
+  // routeEvent, in the order passed to Use. See middleware.go.
+  middleware []Middleware
+
+  // disconnectHooks are run in RemoveClient alongside OnDisconnect, in
+  // the order they were registered via onDisconnect. Unlike OnDisconnect,
+  // this is an internal append-only list so middleware (e.g.
+  // RateLimitMiddleware) can register its own cleanup without clobbering
+  // whatever the application assigns to the public OnDisconnect field.
+  disconnectHooks []ConnectionHandler
+
+  // rooms holds every Room created via Manager.Room and not yet pruned
+  // (a Room is pruned when its last member leaves, so one that never had
+  // a member stays tracked), keyed by name and guarded by roomsMu - kept
+  // separate from mu, like
+  // pendingMu, since Room.Leave prunes it from inside RemoveClient's own
+  // mu-locked section.
+  roomsMu sync.RWMutex
+  rooms   map[string]*Room
+
+  // pending tracks in-flight Request calls, keyed by the request Event's
+  // ID, so a correlated reply (or a client disconnect) can resolve them.
+  // Guarded by pendingMu, deliberately separate from mu since it is on
+  // the hot path of every inbound Event.
+  pendingMu sync.Mutex
+  pending   map[string]pendingRequest
+
+  // backplane, when set via SetBackplane, carries broadcasts and
+  // roomcasts to other Manager instances. See backplane.go.
+  backplane Backplane
+
+  // authenticator, when set via SetAuthenticator, is run by LoginHandler
+  // for every login request; while it is set, Serve requires a valid OTP
+  // for every WebSocket upgrade. See auth.go.
+  authenticator Authenticator
+  // otps is the short-lived OTP store backing LoginHandler and Serve's
+  // upgrade check. Created lazily by SetAuthenticator, so it is nil until
+  // an Authenticator is configured.
+  otps *RetentionMap
+
+  // codec controls how Events are translated to and from wire bytes. It
+  // defaults to JSONCodec and can be overridden via SetCodec.
+  codec Codec
+}
+
+// SetCodec configures c as the wire encoding for every Client connected
+// to m, both for inbound reads in readMessages and outbound writes in
+// writeMessages.
+func (m *Manager) SetCodec(c Codec) {
+  m.mu.Lock()
+  defer m.mu.Unlock()
+  m.codec = c
+}
+
+// getCodec returns the Manager's current Codec, safe for concurrent use
+// alongside SetCodec.
+func (m *Manager) getCodec() Codec {
+  m.mu.RLock()
+  defer m.mu.RUnlock()
+  return m.codec
 }
 
 func (m *Manager) routeEvent(event Event, c *Client) error {
+	if event.ReplyTo != "" {
+		m.resolveReply(event)
+		return nil
+	}
+
 	if handler, ok := m.handlers[event.Type]; ok {
-		if err := handler(event, c); err != nil {
+		if err := m.wrap(handler)(event, c); err != nil {
 			return err
 		}
 		return nil
@@ -185,6 +365,17 @@ func (m *Manager) routeEvent(event Event, c *Client) error {
 // Serve is not to be called directly, rather
 // to be used as a callback for HTTP request handling.
 func (m *Manager) Serve(w http.ResponseWriter, r *http.Request) {
+  var identity string
+  if m.getAuthenticator() != nil {
+    otp := r.URL.Query().Get("otp")
+    var ok bool
+    identity, _, ok = m.otps.Verify(otp)
+    if !ok {
+      http.Error(w, "unauthorized", http.StatusUnauthorized)
+      return
+    }
+  }
+
 	conn, err := websocketUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Fatal(err)
@@ -192,6 +383,7 @@ func (m *Manager) Serve(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := newClient(conn, m)
+  client.Identity = identity
 	m.AddClient(client)
 
   if m.OnConnect != nil {
@@ -209,6 +401,16 @@ func (m *Manager) AddClient(client *Client) {
 	m.clients[client] = true
 }
 
+// onDisconnect registers hook to run in RemoveClient, in addition to
+// OnDisconnect, without disturbing whatever the application has or later
+// assigns to that public field. Meant for internal use by middleware
+// (see RateLimitMiddleware).
+func (m *Manager) onDisconnect(hook ConnectionHandler) {
+  m.mu.Lock()
+  defer m.mu.Unlock()
+  m.disconnectHooks = append(m.disconnectHooks, hook)
+}
+
 func (m *Manager) RemoveClient(client *Client) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -217,18 +419,34 @@ func (m *Manager) RemoveClient(client *Client) {
     if m.OnDisconnect != nil {
       m.OnDisconnect(client)
     }
+    for _, hook := range m.disconnectHooks {
+      hook(client)
+    }
+    for _, name := range client.Rooms() {
+      m.roomsMu.RLock()
+      room, ok := m.rooms[name]
+      m.roomsMu.RUnlock()
+      if ok {
+        room.Leave(client)
+      }
+    }
+    m.cancelPendingFor(client)
 		client.connection.Close()
 		delete(m.clients, client)
 	}
 }
 
 
-func newManager() *Manager {
+func newManager(opts Options) *Manager {
 	m := &Manager{
 		clients:  make(ClientList),
 		handlers: make(map[string]EventHandler),
+    options: opts,
     OnConnect: nil,
     OnDisconnect: nil,
+    rooms: make(map[string]*Room),
+    pending: make(map[string]pendingRequest),
+    codec: JSONCodec{},
 	}
 
 	m.defaultEventHandlers()
@@ -253,22 +471,48 @@ func SendMessage(event Event, c *Client) error {
 }
 */
 
+// ChangeRoom leaves every room c currently belongs to and joins the room
+// named by the event payload. To join additional rooms without leaving
+// the existing ones, use EventJoinRoom instead.
 func ChangeRoom(event Event, c *Client) error {
-	c.room = string(event.Payload)
-  c.egress <- Event{
+  for _, name := range c.Rooms() {
+    c.manager.Room(name).Leave(c)
+  }
+
+  roomName := string(event.Payload)
+  c.manager.Room(roomName).Join(c)
+
+  c.send(Event{
     Type: "set_room",
     Payload: event.Payload,
     From: event.From,
-  }
+  })
 	return nil
 }
 
+// JoinRoomHandler adds c to the room named by the event payload, alongside
+// any rooms it already belongs to.
+func JoinRoomHandler(event Event, c *Client) error {
+  c.manager.Room(string(event.Payload)).Join(c)
+  return nil
+}
+
+// LeaveRoomHandler removes c from the room named by the event payload.
+func LeaveRoomHandler(event Event, c *Client) error {
+  c.manager.Room(string(event.Payload)).Leave(c)
+  return nil
+}
+
 func (m *Manager) defaultEventHandlers() {
   m.AddEventHandler(EventChangeRoom, ChangeRoom)
+  m.AddEventHandler(EventJoinRoom, JoinRoomHandler)
+  m.AddEventHandler(EventLeaveRoom, LeaveRoomHandler)
 }
 
 const (
-	EventChangeRoom  = "change_room"
+	EventChangeRoom = "change_room"
+	EventJoinRoom   = "join_room"
+	EventLeaveRoom  = "leave_room"
 )
 
 func (m *Manager) AddEventHandler(msgType string, handler EventHandler) { // Adds an event handler of message type 'msgType' and handler function 'handler'
@@ -276,5 +520,13 @@ func (m *Manager) AddEventHandler(msgType string, handler EventHandler) { // Add
 }
 
 func Socky() *Manager {
-  return newManager()
+  return newManager(defaultOptions())
+}
+
+// SockyWithOptions is like Socky but lets the caller override the default
+// egress buffering, backpressure and WebSocket limits. Fields left at
+// their zero value fall back to defaultOptions rather than disabling the
+// corresponding limit; see Options.withDefaults.
+func SockyWithOptions(opts Options) *Manager {
+  return newManager(opts.withDefaults())
 }